@@ -0,0 +1,79 @@
+// Command unhit-report fetches /endpoints from a running server and writes
+// JUnit and/or Cobertura coverage reports for its registered routes, so a
+// CI pipeline can gate on endpoint coverage the same way it gates on unit
+// test coverage.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	infrastructure "github.com/tanel/httprouter-unhit"
+)
+
+func main() {
+	endpointsURL := flag.String("endpoints-url", "http://localhost:8080/endpoints", "URL of the running server's /endpoints JSON endpoint")
+	junitPath := flag.String("junit", "", "path to write a JUnit XML report to (skipped if empty)")
+	coberturaPath := flag.String("cobertura", "", "path to write a Cobertura XML report to (skipped if empty)")
+	flag.Parse()
+
+	if *junitPath == "" && *coberturaPath == "" {
+		fmt.Fprintln(os.Stderr, "unhit-report: at least one of -junit or -cobertura must be set")
+		os.Exit(1)
+	}
+
+	endpoints, err := fetchEndpoints(*endpointsURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unhit-report:", err)
+		os.Exit(1)
+	}
+
+	if *junitPath != "" {
+		if err := writeReport(*junitPath, func(w io.Writer) error {
+			return infrastructure.WriteJUnitReport(w, endpoints)
+		}); err != nil {
+			fmt.Fprintln(os.Stderr, "unhit-report:", err)
+			os.Exit(1)
+		}
+	}
+
+	if *coberturaPath != "" {
+		if err := writeReport(*coberturaPath, func(w io.Writer) error {
+			return infrastructure.WriteCoberturaReport(w, endpoints)
+		}); err != nil {
+			fmt.Fprintln(os.Stderr, "unhit-report:", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func fetchEndpoints(url string) ([]infrastructure.ReportEndpoint, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", url, resp.Status)
+	}
+
+	var endpoints []infrastructure.ReportEndpoint
+	if err := json.NewDecoder(resp.Body).Decode(&endpoints); err != nil {
+		return nil, fmt.Errorf("decoding response from %s: %w", url, err)
+	}
+	return endpoints, nil
+}
+
+func writeReport(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return write(f)
+}
@@ -0,0 +1,97 @@
+package infrastructure
+
+import "github.com/julienschmidt/httprouter"
+
+// defaultMethodOrder is the tie-breaking order /endpoints sorts routes by
+// when two routes share a path. RegisterMethod appends custom methods to
+// this list, in the order they were registered.
+var defaultMethodOrder = []string{
+	"GET",
+	"HEAD",
+	"OPTIONS",
+	"POST",
+	"PUT",
+	"PATCH",
+	"DELETE",
+}
+
+// Method is a shortcut for router.Handle(method, path, handle), for HTTP
+// methods without a dedicated shortcut.
+func (r *Router) Method(method, path string, handle httprouter.Handle) {
+	r.Handle(method, path, handle)
+}
+
+// PROPFIND is a shortcut for router.Handle("PROPFIND", path, handle).
+func (r *Router) PROPFIND(path string, handle httprouter.Handle) {
+	r.Method("PROPFIND", path, handle)
+}
+
+// PROPPATCH is a shortcut for router.Handle("PROPPATCH", path, handle).
+func (r *Router) PROPPATCH(path string, handle httprouter.Handle) {
+	r.Method("PROPPATCH", path, handle)
+}
+
+// MKCOL is a shortcut for router.Handle("MKCOL", path, handle).
+func (r *Router) MKCOL(path string, handle httprouter.Handle) {
+	r.Method("MKCOL", path, handle)
+}
+
+// COPY is a shortcut for router.Handle("COPY", path, handle).
+func (r *Router) COPY(path string, handle httprouter.Handle) {
+	r.Method("COPY", path, handle)
+}
+
+// MOVE is a shortcut for router.Handle("MOVE", path, handle).
+func (r *Router) MOVE(path string, handle httprouter.Handle) {
+	r.Method("MOVE", path, handle)
+}
+
+// LOCK is a shortcut for router.Handle("LOCK", path, handle).
+func (r *Router) LOCK(path string, handle httprouter.Handle) {
+	r.Method("LOCK", path, handle)
+}
+
+// UNLOCK is a shortcut for router.Handle("UNLOCK", path, handle).
+func (r *Router) UNLOCK(path string, handle httprouter.Handle) {
+	r.Method("UNLOCK", path, handle)
+}
+
+// REPORT is a shortcut for router.Handle("REPORT", path, handle).
+func (r *Router) REPORT(path string, handle httprouter.Handle) {
+	r.Method("REPORT", path, handle)
+}
+
+// RegisterMethod declares a custom method, e.g. one of the WebDAV verbs, so
+// /endpoints sorts it into a stable position instead of lumping every
+// unrecognized method together at the end. This makes the JSON output diffable
+// across runs, so a CI checker can assert every declared method+path pair was
+// exercised.
+func (r *Router) RegisterMethod(method string) {
+	r.core.registerMethod(method)
+}
+
+func (c *routerCore) registerMethod(method string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	for _, m := range c.methodOrder {
+		if m == method {
+			return
+		}
+	}
+	c.methodOrder = append(c.methodOrder, method)
+}
+
+// methodIndex returns method's position in the method order, for use as a
+// sort key. Methods that haven't been declared via RegisterMethod sort
+// after every declared one, but still in a stable, deterministic position
+// relative to each other.
+func (c *routerCore) methodIndex(method string) int {
+	c.m.Lock()
+	defer c.m.Unlock()
+	for i, m := range c.methodOrder {
+		if m == method {
+			return i
+		}
+	}
+	return len(c.methodOrder)
+}
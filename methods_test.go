@@ -0,0 +1,73 @@
+package infrastructure_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	infrastructure "github.com/tanel/httprouter-unhit"
+)
+
+func TestWebDAVShortcutsRegisterTheExpectedMethod(t *testing.T) {
+	r := infrastructure.New()
+	defer r.Close()
+
+	var got string
+	r.PROPFIND("/locks", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		got = req.Method
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("PROPFIND", "/locks", nil))
+	if rec.Code == http.StatusNotFound {
+		t.Fatalf("PROPFIND /locks: route not found")
+	}
+	if got != "PROPFIND" {
+		t.Fatalf("expected handler to observe method PROPFIND, got %q", got)
+	}
+}
+
+func TestRegisterMethodOrdersEndpointsByDeclarationOrder(t *testing.T) {
+	r := infrastructure.New()
+	defer r.Close()
+
+	r.RegisterMethod("REPORT")
+	r.RegisterMethod("PROPFIND")
+
+	r.REPORT("/x", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {})
+	r.PROPFIND("/x", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {})
+	r.GET("/x", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/endpoints", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /endpoints: status %d", rec.Code)
+	}
+
+	var snapshots []struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshots); err != nil {
+		t.Fatalf("decode /endpoints body: %v (body=%s)", err, rec.Body.String())
+	}
+
+	var order []string
+	for _, snap := range snapshots {
+		if snap.Path == "/x" {
+			order = append(order, snap.Method)
+		}
+	}
+
+	want := []string{"GET", "REPORT", "PROPFIND"}
+	if len(order) != len(want) {
+		t.Fatalf("got method order %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("got method order %v, want %v", order, want)
+		}
+	}
+}
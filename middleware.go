@@ -0,0 +1,47 @@
+package infrastructure
+
+import "github.com/julienschmidt/httprouter"
+
+// Middleware wraps a httprouter.Handle to add cross-cutting behaviour
+// (logging, auth, recovery, ...) around it.
+type Middleware func(httprouter.Handle) httprouter.Handle
+
+// Use appends mw to the Router's middleware stack. Middlewares run in the
+// order they were added, outermost first, and apply to every route
+// registered on this Router (and, via Group/With, its descendants) from
+// then on.
+func (r *Router) Use(mw ...Middleware) {
+	r.middlewares = append(r.middlewares, mw...)
+}
+
+// With returns a derived Router sharing this Router's core and prefix, with
+// mw appended to its middleware stack. The receiver is left untouched, so
+// With is safe to use for one-off routes that need extra middleware.
+func (r *Router) With(mw ...Middleware) *Router {
+	return &Router{
+		core:        r.core,
+		prefix:      r.prefix,
+		middlewares: append(append([]Middleware{}, r.middlewares...), mw...),
+	}
+}
+
+// Group returns a derived Router sharing this Router's core and middleware
+// stack, with prefix appended to its path prefix, and calls fn with it. It's
+// the place to register the sub-router's routes.
+func (r *Router) Group(prefix string, fn func(r *Router)) {
+	fn(&Router{
+		core:        r.core,
+		prefix:      r.prefix + prefix,
+		middlewares: append([]Middleware{}, r.middlewares...),
+	})
+}
+
+// chain wraps handle with the Router's middlewares, outermost first, so the
+// first Middleware passed to Use runs first and the last one runs closest
+// to handle.
+func (r *Router) chain(handle httprouter.Handle) httprouter.Handle {
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handle = r.middlewares[i](handle)
+	}
+	return handle
+}
@@ -0,0 +1,99 @@
+package infrastructure_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	infrastructure "github.com/tanel/httprouter-unhit"
+)
+
+func TestMiddlewareOrderingOutermostFirst(t *testing.T) {
+	r := infrastructure.New()
+	defer r.Close()
+
+	var order []string
+	mark := func(name string) infrastructure.Middleware {
+		return func(next httprouter.Handle) httprouter.Handle {
+			return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+				order = append(order, name)
+				next(w, req, p)
+			}
+		}
+	}
+
+	r.Use(mark("first"), mark("second"))
+	r.GET("/ping", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		order = append(order, "handler")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := []string{"first", "second", "handler"}
+	if len(order) != len(want) {
+		t.Fatalf("got order %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("got order %v, want %v", order, want)
+		}
+	}
+}
+
+func TestGroupInheritsPrefixAndMiddleware(t *testing.T) {
+	r := infrastructure.New()
+	defer r.Close()
+
+	var hits int
+	r.Use(func(next httprouter.Handle) httprouter.Handle {
+		return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+			hits++
+			next(w, req, p)
+		}
+	})
+
+	r.Group("/api", func(sub *infrastructure.Router) {
+		sub.GET("/widgets", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/widgets", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected grouped route to be reachable at /api/widgets, got status %d", rec.Code)
+	}
+	if hits != 1 {
+		t.Fatalf("expected the parent Router's middleware to run once for the grouped route, got %d", hits)
+	}
+}
+
+func TestWithLeavesReceiverUntouched(t *testing.T) {
+	r := infrastructure.New()
+	defer r.Close()
+
+	var extraRan bool
+	extra := func(next httprouter.Handle) httprouter.Handle {
+		return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+			extraRan = true
+			next(w, req, p)
+		}
+	}
+
+	derived := r.With(extra)
+	derived.GET("/one", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {})
+	r.GET("/two", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/two", nil))
+	if extraRan {
+		t.Fatal("extra middleware passed to With ran for a route registered on the original Router")
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/one", nil))
+	if !extraRan {
+		t.Fatal("extra middleware passed to With did not run for a route registered on the derived Router")
+	}
+}
@@ -0,0 +1,308 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// Schema is a minimal JSON Schema subset - just enough to describe the
+// simple request/response shapes typical of JSON APIs. OperationInfo
+// accepts either a *Schema built by hand, or any other Go value, which
+// SchemaFor reflects into one.
+type Schema struct {
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+}
+
+// OperationInfo describes an operation for OpenAPI generation. RequestBody
+// and the values in Responses may each be a *Schema, or any other Go value
+// to be reflected into one with SchemaFor.
+type OperationInfo struct {
+	Summary     string
+	Tags        []string
+	RequestBody interface{}
+	Responses   map[int]interface{}
+}
+
+// Describe attaches OpenAPI metadata to the route registered with method
+// and path, which is served at /endpoints/openapi.json. It can be called
+// before or after Handle registers the route itself.
+func (r *Router) Describe(method, path string, op OperationInfo) {
+	r.core.describeRoute(method, r.prefix+path, op)
+}
+
+func (c *routerCore) describeRoute(method, path string, op OperationInfo) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.operations == nil {
+		c.operations = make(map[RouteKey]OperationInfo)
+	}
+	c.operations[RouteKey{Method: method, Path: path}] = op
+}
+
+func (c *routerCore) operationFor(key RouteKey) OperationInfo {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.operations[key]
+}
+
+type openAPIDocument struct {
+	OpenAPI string                                  `json:"openapi"`
+	Info    openAPIInfo                             `json:"info"`
+	Paths   map[string]map[string]*openAPIOperation `json:"paths"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+type openAPIRequestBody struct {
+	Content map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// pathParamPattern matches httprouter's :name and *name path segments.
+var pathParamPattern = regexp.MustCompile(`[:*]([^/]+)`)
+
+// openAPIDocument composes the OpenAPI document for the Router's registered
+// routes, flagging routes that haven't been hit yet in their description.
+func (r *Router) openAPIDocument() *openAPIDocument {
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    openAPIInfo{Title: "API", Version: "1.0.0"},
+		Paths:   make(map[string]map[string]*openAPIOperation),
+	}
+
+	for _, snap := range r.filterEndpoints(false) {
+		op := r.core.operationFor(RouteKey{Method: snap.Method, Path: snap.Path})
+
+		description := "Has been exercised by at least one observed request."
+		if snap.Hits == 0 {
+			description = "Not yet exercised by any observed request."
+		}
+
+		openAPIPath := openAPIPathTemplate(snap.Path)
+		pathItem, ok := doc.Paths[openAPIPath]
+		if !ok {
+			pathItem = make(map[string]*openAPIOperation)
+			doc.Paths[openAPIPath] = pathItem
+		}
+
+		pathItem[strings.ToLower(snap.Method)] = &openAPIOperation{
+			Summary:     op.Summary,
+			Description: description,
+			Tags:        op.Tags,
+			Parameters:  pathParameters(snap.Path),
+			RequestBody: requestBodyFor(op.RequestBody),
+			Responses:   responsesFor(op.Responses),
+		}
+	}
+
+	return doc
+}
+
+// openAPIPathTemplate rewrites httprouter's :name and *name path segments
+// into OpenAPI's {name} template syntax, e.g. "/widgets/:id" becomes
+// "/widgets/{id}".
+func openAPIPathTemplate(path string) string {
+	return pathParamPattern.ReplaceAllString(path, "{$1}")
+}
+
+func pathParameters(path string) []openAPIParameter {
+	matches := pathParamPattern.FindAllStringSubmatch(path, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	params := make([]openAPIParameter, 0, len(matches))
+	for _, m := range matches {
+		params = append(params, openAPIParameter{
+			Name:     m[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	return params
+}
+
+func requestBodyFor(body interface{}) *openAPIRequestBody {
+	schema := schemaOf(body)
+	if schema == nil {
+		return nil
+	}
+	return &openAPIRequestBody{Content: map[string]openAPIMediaType{"application/json": {Schema: schema}}}
+}
+
+func responsesFor(responses map[int]interface{}) map[string]openAPIResponse {
+	out := make(map[string]openAPIResponse, len(responses))
+	for status, body := range responses {
+		resp := openAPIResponse{Description: http.StatusText(status)}
+		if schema := schemaOf(body); schema != nil {
+			resp.Content = map[string]openAPIMediaType{"application/json": {Schema: schema}}
+		}
+		out[strconv.Itoa(status)] = resp
+	}
+	if len(out) == 0 {
+		out["200"] = openAPIResponse{Description: http.StatusText(http.StatusOK)}
+	}
+	return out
+}
+
+func schemaOf(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	if schema, ok := v.(*Schema); ok {
+		return schema
+	}
+	return SchemaFor(v)
+}
+
+// SchemaFor reflects a Go value into a best-effort JSON Schema, following
+// its "json" struct tags. It's meant for simple, JSON-shaped values;
+// anything more exotic should be described with a hand-built *Schema
+// instead.
+func SchemaFor(v interface{}) *Schema {
+	return schemaForType(reflect.TypeOf(v))
+}
+
+func schemaForType(t reflect.Type) *Schema {
+	if t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+
+		name := field.Name
+		omitempty := false
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" {
+					omitempty = true
+				}
+			}
+		}
+
+		schema.Properties[name] = schemaForType(field.Type)
+		if !omitempty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// swaggerUIPage loads its CSS/JS bundle from unpkg.com at request time,
+// which keeps this package dependency-free but means /endpoints/docs needs
+// outbound internet access to render - it won't work on an airgapped or
+// otherwise fully offline host. Self-host swagger-ui-dist and point these
+// two URLs at it if that matters for your deployment.
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>API docs</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function() {
+      SwaggerUIBundle({
+        url: '` + endpointsOpenAPIPath + `',
+        dom_id: '#swagger-ui',
+      });
+    };
+  </script>
+</body>
+</html>
+`
+
+func (r *Router) handleGetOpenAPI(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	b, err := json.MarshalIndent(r.openAPIDocument(), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (r *Router) handleGetDocs(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(swaggerUIPage))
+}
@@ -0,0 +1,121 @@
+package infrastructure_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	infrastructure "github.com/tanel/httprouter-unhit"
+)
+
+type widget struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Note string `json:"note,omitempty"`
+}
+
+func TestSchemaForReflectsStructTags(t *testing.T) {
+	schema := infrastructure.SchemaFor(widget{})
+
+	if schema.Type != "object" {
+		t.Fatalf("expected object schema, got %q", schema.Type)
+	}
+	if schema.Properties["id"] == nil || schema.Properties["id"].Type != "integer" {
+		t.Fatalf("expected integer property id, got %+v", schema.Properties["id"])
+	}
+	if schema.Properties["name"] == nil || schema.Properties["name"].Type != "string" {
+		t.Fatalf("expected string property name, got %+v", schema.Properties["name"])
+	}
+
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	if !required["id"] || !required["name"] {
+		t.Fatalf("expected id and name to be required, got %v", schema.Required)
+	}
+	if required["note"] {
+		t.Fatalf("expected omitempty field note to not be required, got %v", schema.Required)
+	}
+}
+
+func TestOpenAPIDocumentIncludesDescribedRoute(t *testing.T) {
+	r := infrastructure.New()
+	defer r.Close()
+
+	r.GET("/widgets/:id", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {})
+	r.Describe(http.MethodGet, "/widgets/:id", infrastructure.OperationInfo{
+		Summary:   "Get a widget",
+		Responses: map[int]interface{}{200: widget{}},
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/endpoints/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /endpoints/openapi.json: status %d", rec.Code)
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]map[string]struct {
+			Summary    string `json:"summary"`
+			Parameters []struct {
+				Name string `json:"name"`
+				In   string `json:"in"`
+			} `json:"parameters"`
+			Responses map[string]struct {
+				Content map[string]struct {
+					Schema struct {
+						Type string `json:"type"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode openapi document: %v (body=%s)", err, rec.Body.String())
+	}
+
+	if _, ok := doc.Paths["/widgets/:id"]; ok {
+		t.Fatalf("expected httprouter's :id syntax to be rewritten to {id}, got raw key in %+v", doc.Paths)
+	}
+	op, ok := doc.Paths["/widgets/{id}"]["get"]
+	if !ok {
+		t.Fatalf("expected a GET operation for /widgets/{id}, got %+v", doc.Paths)
+	}
+	if op.Summary != "Get a widget" {
+		t.Fatalf("expected summary to round-trip, got %q", op.Summary)
+	}
+	if len(op.Parameters) != 1 || op.Parameters[0].Name != "id" || op.Parameters[0].In != "path" {
+		t.Fatalf("expected a single path parameter named id, got %+v", op.Parameters)
+	}
+	if resp, ok := op.Responses["200"]; !ok || resp.Content["application/json"].Schema.Type != "object" {
+		t.Fatalf("expected a 200 response with an object schema, got %+v", op.Responses)
+	}
+}
+
+func TestOpenAPIDocumentTemplatesWildcardPaths(t *testing.T) {
+	r := infrastructure.New()
+	defer r.Close()
+
+	r.GET("/files/*filepath", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/endpoints/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /endpoints/openapi.json: status %d", rec.Code)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode openapi document: %v (body=%s)", err, rec.Body.String())
+	}
+
+	if _, ok := doc.Paths["/files/{filepath}"]; !ok {
+		t.Fatalf("expected wildcard route to be templated as /files/{filepath}, got %+v", doc.Paths)
+	}
+}
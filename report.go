@@ -0,0 +1,151 @@
+package infrastructure
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// ReportEndpoint is the minimal per-route view used to render coverage
+// reports. It's shared between a Router's own live endpoints and
+// cmd/unhit-report, which rebuilds it from a remote server's /endpoints
+// JSON response.
+type ReportEndpoint struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Hits   int64  `json:"hits"`
+}
+
+// WriteJUnit writes the Router's registered routes as a JUnit XML report:
+// one testcase per route, with unhit routes reported as failures. CI
+// systems that already gate on JUnit test failures can gate on endpoint
+// coverage the same way.
+func (r *Router) WriteJUnit(w io.Writer) error {
+	return WriteJUnitReport(w, r.reportEndpoints())
+}
+
+// WriteCobertura writes the Router's registered routes as a Cobertura-style
+// coverage report, with line-rate equal to hit routes over total routes.
+func (r *Router) WriteCobertura(w io.Writer) error {
+	return WriteCoberturaReport(w, r.reportEndpoints())
+}
+
+func (r *Router) reportEndpoints() []ReportEndpoint {
+	snapshots := r.filterEndpoints(false)
+	endpoints := make([]ReportEndpoint, len(snapshots))
+	for i, snap := range snapshots {
+		endpoints[i] = ReportEndpoint{Method: snap.Method, Path: snap.Path, Hits: snap.Hits}
+	}
+	return endpoints
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// WriteJUnitReport renders endpoints as a JUnit XML report where each route
+// is a test case and unhit routes are failures.
+func WriteJUnitReport(w io.Writer, endpoints []ReportEndpoint) error {
+	suite := junitTestSuite{Name: "endpoints", Tests: len(endpoints)}
+	for _, ep := range endpoints {
+		tc := junitTestCase{ClassName: ep.Method, Name: ep.Path}
+		if ep.Hits == 0 {
+			tc.Failure = &junitFailure{Message: "endpoint not exercised"}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	return writeXML(w, suite)
+}
+
+type coberturaLine struct {
+	Number int   `xml:"number,attr"`
+	Hits   int64 `xml:"hits,attr"`
+}
+
+type coberturaClass struct {
+	Name     string          `xml:"name,attr"`
+	Filename string          `xml:"filename,attr"`
+	LineRate string          `xml:"line-rate,attr"`
+	Lines    []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaPackage struct {
+	Name     string           `xml:"name,attr"`
+	LineRate string           `xml:"line-rate,attr"`
+	Classes  []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaCoverage struct {
+	XMLName      xml.Name           `xml:"coverage"`
+	LineRate     string             `xml:"line-rate,attr"`
+	LinesCovered int                `xml:"lines-covered,attr"`
+	LinesValid   int                `xml:"lines-valid,attr"`
+	Version      string             `xml:"version,attr"`
+	Packages     []coberturaPackage `xml:"packages>package"`
+}
+
+// WriteCoberturaReport renders endpoints as a Cobertura-style coverage
+// report, treating each route as a single line: hit routes are covered,
+// unhit routes are not.
+func WriteCoberturaReport(w io.Writer, endpoints []ReportEndpoint) error {
+	pkg := coberturaPackage{Name: "endpoints"}
+	var covered int
+	for _, ep := range endpoints {
+		rate := "0"
+		if ep.Hits > 0 {
+			rate = "1"
+			covered++
+		}
+		pkg.Classes = append(pkg.Classes, coberturaClass{
+			Name:     ep.Method + " " + ep.Path,
+			Filename: ep.Method + " " + ep.Path,
+			LineRate: rate,
+			Lines:    []coberturaLine{{Number: 1, Hits: ep.Hits}},
+		})
+	}
+	pkg.LineRate = lineRate(covered, len(endpoints))
+
+	coverage := coberturaCoverage{
+		LineRate:     lineRate(covered, len(endpoints)),
+		LinesCovered: covered,
+		LinesValid:   len(endpoints),
+		Version:      "1.0",
+		Packages:     []coberturaPackage{pkg},
+	}
+	return writeXML(w, coverage)
+}
+
+func lineRate(covered, total int) string {
+	if total == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%.4f", float64(covered)/float64(total))
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
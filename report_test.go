@@ -0,0 +1,92 @@
+package infrastructure_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	infrastructure "github.com/tanel/httprouter-unhit"
+)
+
+func TestWriteJUnitReportMarksUnhitRoutesAsFailures(t *testing.T) {
+	endpoints := []infrastructure.ReportEndpoint{
+		{Method: "GET", Path: "/hit", Hits: 5},
+		{Method: "GET", Path: "/unhit", Hits: 0},
+	}
+
+	var buf bytes.Buffer
+	if err := infrastructure.WriteJUnitReport(&buf, endpoints); err != nil {
+		t.Fatalf("WriteJUnitReport: %v", err)
+	}
+
+	var suite struct {
+		XMLName  xml.Name `xml:"testsuite"`
+		Tests    int      `xml:"tests,attr"`
+		Failures int      `xml:"failures,attr"`
+		Cases    []struct {
+			Name    string `xml:"name,attr"`
+			Failure *struct {
+				Message string `xml:"message,attr"`
+			} `xml:"failure"`
+		} `xml:"testcase"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &suite); err != nil {
+		t.Fatalf("unmarshal report: %v (body=%s)", err, buf.String())
+	}
+
+	if suite.Tests != 2 || suite.Failures != 1 {
+		t.Fatalf("expected tests=2 failures=1, got tests=%d failures=%d", suite.Tests, suite.Failures)
+	}
+	for _, tc := range suite.Cases {
+		wantFailure := tc.Name == "/unhit"
+		if (tc.Failure != nil) != wantFailure {
+			t.Fatalf("testcase %s: failure presence = %v, want %v", tc.Name, tc.Failure != nil, wantFailure)
+		}
+	}
+}
+
+func TestWriteCoberturaReportComputesLineRate(t *testing.T) {
+	endpoints := []infrastructure.ReportEndpoint{
+		{Method: "GET", Path: "/hit", Hits: 5},
+		{Method: "GET", Path: "/unhit", Hits: 0},
+	}
+
+	var buf bytes.Buffer
+	if err := infrastructure.WriteCoberturaReport(&buf, endpoints); err != nil {
+		t.Fatalf("WriteCoberturaReport: %v", err)
+	}
+
+	var coverage struct {
+		XMLName      xml.Name `xml:"coverage"`
+		LineRate     string   `xml:"line-rate,attr"`
+		LinesCovered int      `xml:"lines-covered,attr"`
+		LinesValid   int      `xml:"lines-valid,attr"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &coverage); err != nil {
+		t.Fatalf("unmarshal report: %v (body=%s)", err, buf.String())
+	}
+
+	if coverage.LinesCovered != 1 || coverage.LinesValid != 2 {
+		t.Fatalf("expected 1/2 lines covered, got %d/%d", coverage.LinesCovered, coverage.LinesValid)
+	}
+	if coverage.LineRate != "0.5000" {
+		t.Fatalf("expected line-rate 0.5000, got %q", coverage.LineRate)
+	}
+}
+
+func TestWriteCoberturaReportHandlesNoEndpoints(t *testing.T) {
+	var buf bytes.Buffer
+	if err := infrastructure.WriteCoberturaReport(&buf, nil); err != nil {
+		t.Fatalf("WriteCoberturaReport: %v", err)
+	}
+
+	var coverage struct {
+		LineRate string `xml:"line-rate,attr"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &coverage); err != nil {
+		t.Fatalf("unmarshal report: %v (body=%s)", err, buf.String())
+	}
+	if coverage.LineRate != "0" {
+		t.Fatalf("expected line-rate 0 for no endpoints, got %q", coverage.LineRate)
+	}
+}
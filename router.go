@@ -1,39 +1,99 @@
 // Use this package as drop-in replacement for httprouter with hit counter.
-// Do not use it in production, as it's slow - using mutex and all.
-// Visit /endpoints or /endpoints/unhit to see hit counter.
+// Hits are tracked with one atomic counter per route, with no router-wide
+// lock on the request path; each route's status and latency breakdown is
+// updated under that route's own mutex.
+// Visit /endpoints or /endpoints/unhit to see hit counter, or
+// /endpoints/metrics for a Prometheus exposition of the same telemetry.
 package infrastructure
 
 import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 )
 
 // Router is a http.Handler which can be used to find untested endpoints.
+//
+// A Router returned by New is the root router. Group and With derive
+// further Routers that share the same underlying httprouter.Router and hit
+// counter, but carry their own middleware stack and path prefix.
 type Router struct {
-	router     *httprouter.Router
-	hitCounter endpointLookupTable
-	m          sync.Mutex
-	// httprouter delegates
+	core        *routerCore
+	middlewares []Middleware
+	prefix      string
+	// httprouter delegates, only meaningful on the root Router
 	NotFound         http.Handler
 	MethodNotAllowed http.Handler
 	PanicHandler     func(http.ResponseWriter, *http.Request, interface{})
 }
 
+// routerCore holds the state shared by a root Router and all Routers
+// derived from it via Group or With.
+type routerCore struct {
+	router    *httprouter.Router
+	endpoints []*endpoint
+	m         sync.Mutex // guards endpoints, operations and methodOrder; never taken by registerHit
+
+	store     HitStore
+	seed      map[RouteKey]int64
+	stopFlush chan struct{}
+	flushDone chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+
+	operations map[RouteKey]OperationInfo
+
+	methodOrder []string
+}
+
+// defaultFlushInterval is how often hit counts are synced to the configured
+// HitStore. It's unrelated to the per-request hot path, which never touches
+// the store.
+const defaultFlushInterval = 30 * time.Second
+
+// Option configures a Router constructed by New.
+type Option func(*routerCore)
+
+// WithStore configures the HitStore a Router seeds its hit counts from on
+// startup, and persists them to on a ticker and on Close. Without this
+// option, hit counts are kept in memory only and lost on restart.
+func WithStore(store HitStore) Option {
+	return func(c *routerCore) {
+		c.store = store
+	}
+}
+
 // Make sure the Router conforms with the http.Handler interface
 var _ http.Handler = New()
 
 // New returns a new initialized Router.
-func New() *Router {
-	return registerPaths(
-		&Router{
-			router:     httprouter.New(),
-			hitCounter: make(endpointLookupTable),
-		},
-	)
+func New(opts ...Option) *Router {
+	core := &routerCore{
+		router:      httprouter.New(),
+		store:       noopHitStore{},
+		methodOrder: append([]string{}, defaultMethodOrder...),
+	}
+	for _, opt := range opts {
+		opt(core)
+	}
+
+	core.loadSeed()
+	r := registerPaths(&Router{core: core})
+	core.startFlushLoop()
+	return r
+}
+
+// Close stops the background flush loop and performs one final flush to the
+// configured HitStore. It is safe to call on any Router derived from the
+// same root via Group or With.
+func (r *Router) Close() error {
+	return r.core.close()
 }
 
 // GET is a shortcut for router.Handle("GET", path, handle).
@@ -73,65 +133,164 @@ func (r *Router) DELETE(path string, handle httprouter.Handle) {
 
 // Handle wraps httprouter functionality.
 func (r *Router) Handle(method, path string, handle httprouter.Handle) {
-	r.registerRoute(method, path, &handle)
+	fullPath := r.prefix + path
+	ep := r.core.registerRoute(method, fullPath)
 
 	interceptingHandle := func(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
-		defer r.registerHit(&handle)
-		handle(w, req, params)
+		atomic.AddInt64(&ep.InFlight, 1)
+		defer atomic.AddInt64(&ep.InFlight, -1)
+
+		rec := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+		// panicked stays true unless handle returns normally, so a panicking
+		// handler is recorded as a 500 instead of rec.Status()'s zero-value
+		// default of 200 - the panic itself keeps propagating to
+		// PanicHandler once this defer returns.
+		panicked := true
+		defer func() {
+			status := rec.Status()
+			if panicked {
+				status = http.StatusInternalServerError
+			}
+			r.core.registerHit(ep, status, time.Since(start), rec.bytes)
+		}()
+
+		handle(rec, req, params)
+		panicked = false
 	}
 
-	r.router.Handle(method, path, interceptingHandle)
+	r.core.router.Handle(method, fullPath, r.chain(interceptingHandle))
 }
 
 // ServeFiles wraps httprouter functionality.
 func (r *Router) ServeFiles(path string, root http.FileSystem) {
-	r.router.ServeFiles(path, root)
+	r.core.router.ServeFiles(r.prefix+path, root)
 }
 
 // ServeHTTP wraps httprouter functionality.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// We have no clue, so ask httprouter to do the actual work.
-	r.router.NotFound = r.NotFound
-	r.router.MethodNotAllowed = r.MethodNotAllowed
-	r.router.PanicHandler = r.PanicHandler
-	r.router.ServeHTTP(w, req)
+	r.core.router.NotFound = r.NotFound
+	r.core.router.MethodNotAllowed = r.MethodNotAllowed
+	r.core.router.PanicHandler = r.PanicHandler
+	r.core.router.ServeHTTP(w, req)
 }
 
 // private methods
 
+// endpoint holds the live, mutable telemetry for a single registered route.
+// Hits, InFlight, BytesWritten and LastHitUnixNano sit on the request hot
+// path and are updated with atomics; statusCounts and latency are also
+// updated on every hit, under a plain per-endpoint mutex.
 type endpoint struct {
 	Method string
 	Path   string
-	Hits   int64
+
+	Hits            int64 // atomic
+	InFlight        int64 // atomic
+	BytesWritten    int64 // atomic
+	LastHitUnixNano int64 // atomic
+
+	mu           sync.Mutex
+	statusCounts map[int]int64
+	latency      *latencyHistogram
+}
+
+func newEndpoint(method, path string) *endpoint {
+	return &endpoint{
+		Method:       method,
+		Path:         path,
+		statusCounts: make(map[int]int64),
+		latency:      newLatencyHistogram(),
+	}
+}
+
+// snapshot copies the endpoint's telemetry into a value safe to marshal
+// concurrently with further hits.
+func (ep *endpoint) snapshot() *endpointSnapshot {
+	ep.mu.Lock()
+	statusCodes := make(map[int]int64, len(ep.statusCounts))
+	for status, count := range ep.statusCounts {
+		statusCodes[status] = count
+	}
+	buckets := ep.latency.cumulative()
+	count := ep.latency.count
+	sumSeconds := time.Duration(ep.latency.sumNanos).Seconds()
+	ep.mu.Unlock()
+
+	snap := &endpointSnapshot{
+		Method:       ep.Method,
+		Path:         ep.Path,
+		Hits:         atomic.LoadInt64(&ep.Hits),
+		InFlight:     atomic.LoadInt64(&ep.InFlight),
+		BytesWritten: atomic.LoadInt64(&ep.BytesWritten),
+		StatusCodes:  statusCodes,
+		Latency: latencySnapshot{
+			Buckets:    buckets,
+			Count:      count,
+			SumSeconds: sumSeconds,
+		},
+	}
+	if last := atomic.LoadInt64(&ep.LastHitUnixNano); last != 0 {
+		t := time.Unix(0, last).UTC()
+		snap.LastHit = &t
+	}
+	return snap
 }
 
-type endpointLookupTable map[*httprouter.Handle]*endpoint
+// endpointSnapshot is the JSON-safe view of an endpoint's telemetry served
+// from /endpoints and /endpoints/unhit.
+type endpointSnapshot struct {
+	Method       string          `json:"method"`
+	Path         string          `json:"path"`
+	Hits         int64           `json:"hits"`
+	InFlight     int64           `json:"inFlight"`
+	BytesWritten int64           `json:"bytesWritten"`
+	LastHit      *time.Time      `json:"lastHit,omitempty"`
+	StatusCodes  map[int]int64   `json:"statusCodes"`
+	Latency      latencySnapshot `json:"latency"`
+}
 
 const (
-	endpointsPath      = "/endpoints"
-	endpointsUnhitPath = "/endpoints/unhit"
+	endpointsPath        = "/endpoints"
+	endpointsUnhitPath   = "/endpoints/unhit"
+	endpointsMetricsPath = "/endpoints/metrics"
+	endpointsOpenAPIPath = "/endpoints/openapi.json"
+	endpointsDocsPath    = "/endpoints/docs"
 )
 
 func registerPaths(r *Router) *Router {
 	r.GET(endpointsPath, r.handleGetEndpoints)
 	r.GET(endpointsUnhitPath, r.handleGetEndpointsUnhit)
+	r.GET(endpointsMetricsPath, r.handleGetEndpointsMetrics)
+	r.GET(endpointsOpenAPIPath, r.handleGetOpenAPI)
+	r.GET(endpointsDocsPath, r.handleGetDocs)
 	return r
 }
 
-func (r *Router) filterEndpoints(unhit bool) []*endpoint {
-	var endpoints []*endpoint
-	for _, endpoint := range r.hitCounter {
-		if unhit && endpoint.Hits > 0 {
+func (r *Router) filterEndpoints(unhit bool) []*endpointSnapshot {
+	var endpoints []*endpointSnapshot
+	for _, ep := range r.core.endpoints {
+		switch ep.Path {
+		case endpointsPath, endpointsUnhitPath, endpointsMetricsPath, endpointsOpenAPIPath, endpointsDocsPath:
 			continue
 		}
 
-		if endpoint.Path == endpointsPath || endpoint.Path == endpointsUnhitPath {
+		snap := ep.snapshot()
+		if unhit && snap.Hits > 0 {
 			continue
 		}
 
-		endpoints = append(endpoints, endpoint)
+		endpoints = append(endpoints, snap)
 	}
 
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		return r.core.methodIndex(endpoints[i].Method) < r.core.methodIndex(endpoints[j].Method)
+	})
+
 	return endpoints
 }
 
@@ -143,7 +302,13 @@ func (r *Router) handleGetEndpointsUnhit(w http.ResponseWriter, req *http.Reques
 	r.writeEndpoints(w, r.filterEndpoints(true))
 }
 
-func (r *Router) writeEndpoints(w http.ResponseWriter, endpoints []*endpoint) {
+func (r *Router) handleGetEndpointsMetrics(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+	writePrometheus(w, r.filterEndpoints(false))
+}
+
+func (r *Router) writeEndpoints(w http.ResponseWriter, endpoints []*endpointSnapshot) {
 	b, err := json.MarshalIndent(endpoints, "  ", "  ")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -157,17 +322,99 @@ func (r *Router) writeEndpoints(w http.ResponseWriter, endpoints []*endpoint) {
 	}
 }
 
-func (r *Router) registerRoute(method, path string, handle *httprouter.Handle) {
-	r.m.Lock()
-	defer r.m.Unlock()
-	r.hitCounter[handle] = &endpoint{
-		Method: method,
-		Path:   path,
+// registerRoute grows the endpoints slice and assigns the route a stable
+// slot. It is only ever called during setup, before the router starts
+// serving requests, so the mutex never contends with registerHit.
+//
+// If the configured HitStore had a hit count for this exact method+path, it
+// seeds the new endpoint's Hits. Renaming a route's path is indistinguishable
+// from registering a brand new one, so its counter starts back at zero.
+func (c *routerCore) registerRoute(method, path string) *endpoint {
+	c.m.Lock()
+	defer c.m.Unlock()
+	ep := newEndpoint(method, path)
+	if hits, ok := c.seed[RouteKey{Method: method, Path: path}]; ok {
+		ep.Hits = hits
 	}
+	c.endpoints = append(c.endpoints, ep)
+	return ep
+}
+
+// loadSeed loads previously persisted hit counts from the configured
+// HitStore, ready for registerRoute to apply as routes are registered.
+func (c *routerCore) loadSeed() {
+	seed, err := c.store.Load()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	c.seed = seed
+}
+
+// startFlushLoop periodically syncs every endpoint's current hit count to
+// the configured HitStore, independent of the request hot path.
+func (c *routerCore) startFlushLoop() {
+	c.stopFlush = make(chan struct{})
+	c.flushDone = make(chan struct{})
+
+	go func() {
+		defer close(c.flushDone)
+
+		ticker := time.NewTicker(defaultFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.flush(); err != nil {
+					fmt.Println(err.Error())
+				}
+			case <-c.stopFlush:
+				return
+			}
+		}
+	}()
+}
+
+// flush records every endpoint's current hit count and commits them to the
+// configured HitStore.
+func (c *routerCore) flush() error {
+	c.m.Lock()
+	endpoints := append([]*endpoint(nil), c.endpoints...)
+	c.m.Unlock()
+
+	for _, ep := range endpoints {
+		key := RouteKey{Method: ep.Method, Path: ep.Path}
+		if err := c.store.Record(key, atomic.LoadInt64(&ep.Hits)); err != nil {
+			return err
+		}
+	}
+	return c.store.Flush()
+}
+
+// close stops the flush loop and performs one last flush. It is safe to
+// call more than once - only the first call does any work, and every call
+// returns the same error.
+func (c *routerCore) close() error {
+	c.closeOnce.Do(func() {
+		if c.stopFlush != nil {
+			close(c.stopFlush)
+			<-c.flushDone
+		}
+		c.closeErr = c.flush()
+	})
+	return c.closeErr
 }
 
-func (r *Router) registerHit(handle *httprouter.Handle) {
-	r.m.Lock()
-	defer r.m.Unlock()
-	r.hitCounter[handle].Hits++
+// registerHit is on the request hot path, so its atomic fields must not
+// lock; only the less frequently read status/latency breakdown does.
+func (c *routerCore) registerHit(ep *endpoint, status int, d time.Duration, bytes int) {
+	atomic.AddInt64(&ep.Hits, 1)
+	atomic.AddInt64(&ep.BytesWritten, int64(bytes))
+	atomic.StoreInt64(&ep.LastHitUnixNano, time.Now().UnixNano())
+
+	ep.mu.Lock()
+	ep.statusCounts[status]++
+	ep.latency.observe(d)
+	ep.mu.Unlock()
 }
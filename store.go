@@ -0,0 +1,115 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// RouteKey identifies a registered route for persistence purposes. Hit
+// counts are matched to stored counts by (method, path); if a route's path
+// changes, it is indistinguishable from a brand new route and its counter
+// resets cleanly rather than carrying over stale data.
+type RouteKey struct {
+	Method string
+	Path   string
+}
+
+// HitStore persists per-route hit counts across process restarts. Load is
+// called once at startup to seed existing routes. Record is called
+// periodically with each route's current cumulative hit count; Flush
+// commits whatever Record has staged since the last call. Implementations
+// must be safe for concurrent use.
+type HitStore interface {
+	Load() (map[RouteKey]int64, error)
+	Record(key RouteKey, hits int64) error
+	Flush() error
+}
+
+// noopHitStore is the default HitStore: it remembers nothing, so hit counts
+// always start at zero and Close has nothing to persist.
+type noopHitStore struct{}
+
+func (noopHitStore) Load() (map[RouteKey]int64, error) { return nil, nil }
+func (noopHitStore) Record(RouteKey, int64) error      { return nil }
+func (noopHitStore) Flush() error                      { return nil }
+
+// fileHitRecord is the on-disk JSON representation of a single route's hit
+// count.
+type fileHitRecord struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Hits   int64  `json:"hits"`
+}
+
+// FileHitStore is a HitStore backed by a single JSON file.
+type FileHitStore struct {
+	path string
+	mu   sync.Mutex
+	hits map[RouteKey]int64
+}
+
+// NewFileHitStore returns a FileHitStore that reads from and writes to path.
+// The file doesn't need to exist yet; it's created on the first Flush.
+func NewFileHitStore(path string) *FileHitStore {
+	return &FileHitStore{path: path, hits: make(map[RouteKey]int64)}
+}
+
+// Load reads previously persisted hit counts from disk. A missing or empty
+// file isn't an error - it just means there's nothing to seed yet, which is
+// also the state a file can be left in by a crash between creation and the
+// first Flush.
+func (s *FileHitStore) Load() (map[RouteKey]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	var records []fileHitRecord
+	if err := json.Unmarshal(b, &records); err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[RouteKey]int64, len(records))
+	for _, rec := range records {
+		key := RouteKey{Method: rec.Method, Path: rec.Path}
+		loaded[key] = rec.Hits
+		s.hits[key] = rec.Hits
+	}
+	return loaded, nil
+}
+
+// Record stages the current hit count for key. It isn't written to disk
+// until Flush is called.
+func (s *FileHitStore) Record(key RouteKey, hits int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hits[key] = hits
+	return nil
+}
+
+// Flush writes all staged hit counts to disk as a single JSON document.
+func (s *FileHitStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]fileHitRecord, 0, len(s.hits))
+	for key, hits := range s.hits {
+		records = append(records, fileHitRecord{Method: key.Method, Path: key.Path, Hits: hits})
+	}
+
+	b, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0o644)
+}
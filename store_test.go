@@ -0,0 +1,118 @@
+package infrastructure_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	infrastructure "github.com/tanel/httprouter-unhit"
+)
+
+// externalHitStore proves HitStore is implementable from outside the
+// package: RouteKey must be exported for this to compile.
+type externalHitStore struct{}
+
+func (externalHitStore) Load() (map[infrastructure.RouteKey]int64, error) { return nil, nil }
+func (externalHitStore) Record(infrastructure.RouteKey, int64) error      { return nil }
+func (externalHitStore) Flush() error                                     { return nil }
+
+var _ infrastructure.HitStore = externalHitStore{}
+
+func TestRouterCloseIsIdempotent(t *testing.T) {
+	r := infrastructure.New()
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestFileHitStoreLoadHandlesEmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hits.json")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	store := infrastructure.NewFileHitStore(path)
+	hits, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load on empty file: %v", err)
+	}
+	if len(hits) != 0 {
+		t.Fatalf("expected no seeded hits, got %v", hits)
+	}
+}
+
+func TestRouterSeedsAndPersistsHitsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hits.json")
+
+	r1 := infrastructure.New(infrastructure.WithStore(infrastructure.NewFileHitStore(path)))
+	r1.GET("/widgets", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {})
+	for i := 0; i < 3; i++ {
+		r1.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/widgets", nil))
+	}
+	if err := r1.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r2 := infrastructure.New(infrastructure.WithStore(infrastructure.NewFileHitStore(path)))
+	defer r2.Close()
+	r2.GET("/widgets", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {})
+
+	rec := httptest.NewRecorder()
+	r2.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/endpoints", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /endpoints: status %d", rec.Code)
+	}
+
+	var snapshots []struct {
+		Path string `json:"path"`
+		Hits int64  `json:"hits"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshots); err != nil {
+		t.Fatalf("decode /endpoints body: %v (body=%s)", err, rec.Body.String())
+	}
+
+	var widgets *struct {
+		Path string `json:"path"`
+		Hits int64  `json:"hits"`
+	}
+	for i := range snapshots {
+		if snapshots[i].Path == "/widgets" {
+			widgets = &snapshots[i]
+		}
+	}
+	if widgets == nil {
+		t.Fatalf("no snapshot for /widgets in %+v", snapshots)
+	}
+	if widgets.Hits != 3 {
+		t.Fatalf("expected the second Router to seed 3 hits persisted by the first, got %d", widgets.Hits)
+	}
+}
+
+func TestFileHitStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hits.json")
+	store := infrastructure.NewFileHitStore(path)
+
+	key := infrastructure.RouteKey{Method: "GET", Path: "/widgets"}
+	if err := store.Record(key, 42); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	reloaded, err := infrastructure.NewFileHitStore(path).Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if reloaded[key] != 42 {
+		t.Fatalf("expected hits=42 for %v, got %v", key, reloaded[key])
+	}
+}
@@ -0,0 +1,144 @@
+package infrastructure
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// latencyBucketBounds are the upper bounds (inclusive) of the latency
+// histogram buckets, in ascending order. Anything slower than the last
+// bound falls into the implicit +Inf bucket.
+var latencyBucketBounds = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	25 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	2500 * time.Millisecond,
+}
+
+// latencyHistogram is a fixed-bucket latency histogram for a single
+// endpoint. It is not safe for concurrent use on its own; callers must hold
+// the owning endpoint's mutex.
+type latencyHistogram struct {
+	counts   []int64 // counts[i] = observations with bounds[i-1] < d <= bounds[i]; the last entry is the +Inf overflow bucket
+	count    int64
+	sumNanos int64
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{counts: make([]int64, len(latencyBucketBounds)+1)}
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	idx := len(h.counts) - 1
+	for i, bound := range latencyBucketBounds {
+		if d <= bound {
+			idx = i
+			break
+		}
+	}
+	h.counts[idx]++
+	h.count++
+	h.sumNanos += int64(d)
+}
+
+// cumulative turns the per-bucket counts into the running totals Prometheus
+// histograms expect: each bucket reports the number of observations less
+// than or equal to its bound.
+func (h *latencyHistogram) cumulative() []latencyBucket {
+	buckets := make([]latencyBucket, len(h.counts))
+	var running int64
+	for i, c := range h.counts {
+		running += c
+		le := "+Inf"
+		if i < len(latencyBucketBounds) {
+			le = strconv.FormatFloat(latencyBucketBounds[i].Seconds(), 'f', -1, 64)
+		}
+		buckets[i] = latencyBucket{Le: le, Count: running}
+	}
+	return buckets
+}
+
+type latencyBucket struct {
+	Le    string `json:"le"`
+	Count int64  `json:"count"`
+}
+
+type latencySnapshot struct {
+	Buckets    []latencyBucket `json:"buckets"`
+	Count      int64           `json:"count"`
+	SumSeconds float64         `json:"sumSeconds"`
+}
+
+// statusRecorder wraps a http.ResponseWriter to capture the status code and
+// number of bytes written by a handler, so registerHit can record telemetry
+// without the handler needing to cooperate.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Status returns the recorded status code, defaulting to 200 if the handler
+// never called WriteHeader or Write.
+func (w *statusRecorder) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// writePrometheus renders endpoints as Prometheus text exposition format:
+// a request counter labelled {method,path,status} and a latency histogram
+// labelled {method,path}.
+func writePrometheus(w io.Writer, endpoints []*endpointSnapshot) {
+	fmt.Fprintln(w, "# HELP http_requests_total Total number of HTTP requests handled.")
+	fmt.Fprintln(w, "# TYPE http_requests_total counter")
+	for _, ep := range endpoints {
+		for _, status := range sortedStatusCodes(ep.StatusCodes) {
+			fmt.Fprintf(w, "http_requests_total{method=%q,path=%q,status=%q} %d\n",
+				ep.Method, ep.Path, strconv.Itoa(status), ep.StatusCodes[status])
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP http_request_duration_seconds Latency of HTTP requests.")
+	fmt.Fprintln(w, "# TYPE http_request_duration_seconds histogram")
+	for _, ep := range endpoints {
+		for _, bucket := range ep.Latency.Buckets {
+			fmt.Fprintf(w, "http_request_duration_seconds_bucket{method=%q,path=%q,le=%q} %d\n",
+				ep.Method, ep.Path, bucket.Le, bucket.Count)
+		}
+		fmt.Fprintf(w, "http_request_duration_seconds_sum{method=%q,path=%q} %s\n",
+			ep.Method, ep.Path, strconv.FormatFloat(ep.Latency.SumSeconds, 'f', -1, 64))
+		fmt.Fprintf(w, "http_request_duration_seconds_count{method=%q,path=%q} %d\n",
+			ep.Method, ep.Path, ep.Latency.Count)
+	}
+}
+
+func sortedStatusCodes(statusCodes map[int]int64) []int {
+	codes := make([]int, 0, len(statusCodes))
+	for status := range statusCodes {
+		codes = append(codes, status)
+	}
+	sort.Ints(codes)
+	return codes
+}
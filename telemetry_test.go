@@ -0,0 +1,147 @@
+package infrastructure_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	infrastructure "github.com/tanel/httprouter-unhit"
+)
+
+func TestTelemetrySnapshotTracksStatusAndLatency(t *testing.T) {
+	r := infrastructure.New()
+	defer r.Close()
+
+	r.GET("/widgets", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		time.Sleep(time.Millisecond)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	for i := 0; i < 3; i++ {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/widgets", nil))
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/endpoints", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /endpoints: status %d", rec.Code)
+	}
+
+	var snapshots []struct {
+		Method      string           `json:"method"`
+		Path        string           `json:"path"`
+		Hits        int64            `json:"hits"`
+		StatusCodes map[string]int64 `json:"statusCodes"`
+		Latency     struct {
+			Count int64 `json:"count"`
+		} `json:"latency"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshots); err != nil {
+		t.Fatalf("decode /endpoints body: %v (body=%s)", err, rec.Body.String())
+	}
+
+	var widgets *struct {
+		Method      string           `json:"method"`
+		Path        string           `json:"path"`
+		Hits        int64            `json:"hits"`
+		StatusCodes map[string]int64 `json:"statusCodes"`
+		Latency     struct {
+			Count int64 `json:"count"`
+		} `json:"latency"`
+	}
+	for i := range snapshots {
+		if snapshots[i].Path == "/widgets" {
+			widgets = &snapshots[i]
+		}
+	}
+	if widgets == nil {
+		t.Fatalf("no snapshot for /widgets in %+v", snapshots)
+	}
+	if widgets.Hits != 3 {
+		t.Fatalf("expected 3 hits, got %d", widgets.Hits)
+	}
+	if widgets.StatusCodes["201"] != 3 {
+		t.Fatalf("expected 3 hits recorded under status 201, got %+v", widgets.StatusCodes)
+	}
+	if widgets.Latency.Count != 3 {
+		t.Fatalf("expected 3 latency observations, got %d", widgets.Latency.Count)
+	}
+}
+
+func TestTelemetryRecordsPanicsAsServerErrors(t *testing.T) {
+	r := infrastructure.New()
+	defer r.Close()
+
+	r.PanicHandler = func(w http.ResponseWriter, req *http.Request, v interface{}) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	r.GET("/boom", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		panic("kaboom")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/endpoints", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /endpoints: status %d", rec.Code)
+	}
+
+	var snapshots []struct {
+		Path        string           `json:"path"`
+		StatusCodes map[string]int64 `json:"statusCodes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshots); err != nil {
+		t.Fatalf("decode /endpoints body: %v (body=%s)", err, rec.Body.String())
+	}
+
+	var boom *struct {
+		Path        string           `json:"path"`
+		StatusCodes map[string]int64 `json:"statusCodes"`
+	}
+	for i := range snapshots {
+		if snapshots[i].Path == "/boom" {
+			boom = &snapshots[i]
+		}
+	}
+	if boom == nil {
+		t.Fatalf("no snapshot for /boom in %+v", snapshots)
+	}
+	if boom.StatusCodes["200"] != 0 {
+		t.Fatalf("expected no hits recorded under status 200 for a panicking handler, got %+v", boom.StatusCodes)
+	}
+	if boom.StatusCodes["500"] != 1 {
+		t.Fatalf("expected 1 hit recorded under status 500 for a panicking handler, got %+v", boom.StatusCodes)
+	}
+}
+
+func TestPrometheusExpositionIncludesRegisteredRoute(t *testing.T) {
+	r := infrastructure.New()
+	defer r.Close()
+
+	r.GET("/widgets", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/widgets", nil))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/endpoints/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /endpoints/metrics: status %d", rec.Code)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE http_requests_total counter",
+		`path="/widgets"`,
+		"# TYPE http_request_duration_seconds histogram",
+		"http_request_duration_seconds_bucket",
+		"http_request_duration_seconds_count",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected exposition output to contain %q, got:\n%s", want, body)
+		}
+	}
+}